@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/loggregator/log-archiver/app"
+	"code.cloudfoundry.org/loggregator/logger"
+	"code.cloudfoundry.org/loggregator/plumbing"
+	"code.cloudfoundry.org/loggregator/plumbing/archiver"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	cfg := app.LoadConfig()
+	log := logger.New(cfg.LogFormat, logger.ParseLevel(cfg.LogLevel))
+
+	ctx := context.Background()
+
+	creds, err := plumbing.NewClientCredentials(
+		cfg.LogsProviderCertPath,
+		cfg.LogsProviderKeyPath,
+		cfg.LogsProviderCAPath,
+		cfg.LogsProviderCommonName,
+	)
+	if err != nil {
+		log.Fatalf("failed to load logs provider credentials: %s", err)
+	}
+
+	conn, err := grpc.DialContext(ctx, cfg.LogsProviderAddr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		log.Fatalf("failed to dial logs provider: %s", err)
+	}
+
+	stream, err := v2.NewEgressClient(conn).BatchedReceiver(ctx, &v2.EgressBatchRequest{
+		Selectors: []*v2.Selector{{}},
+	})
+	if err != nil {
+		log.Fatalf("failed to open firehose: %s", err)
+	}
+
+	uploader, err := archiver.NewS3Uploader(ctx, cfg.Bucket, cfg.Region, cfg.Endpoint)
+	if err != nil {
+		log.Fatalf("failed to build S3 uploader: %s", err)
+	}
+
+	manager := archiver.NewManager(cfg.ArchiveRoot, cfg.SweepInterval, cfg.WorkerCount, uploader, archiver.WithManagerLogger(log))
+	go manager.Run(ctx)
+
+	writer := archiver.NewWriter(cfg.ArchiveRoot)
+	if err := writer.Run(stream); err != nil {
+		log.Fatalf("firehose stream ended: %s", err)
+	}
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"code.cloudfoundry.org/loggregator/logger"
+	"code.cloudfoundry.org/loggregator/rlp-gateway/app"
+)
+
+func main() {
+	cfg := app.LoadConfig()
+	log := logger.New(cfg.LogFormat, logger.ParseLevel(cfg.LogLevel))
+
+	app.NewApp(cfg, log).Start()
+}
@@ -0,0 +1,59 @@
+package lats_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/plumbing/archiver"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Log Archiver", func() {
+	It("eventually uploads an emitted v2 log envelope to the fake bucket", func() {
+		root, err := os.MkdirTemp("", "lats-archiver")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(root)
+
+		msgChan := ReadFromRLP("archiver-foo", false)
+
+		env := createLogEnvelopeV2("Archiver message", "archiver-foo")
+		EmitToMetronV2(env)
+
+		var received *v2.Envelope
+		Eventually(msgChan, 10*time.Second).Should(Receive(&received))
+
+		writer := archiver.NewWriter(root)
+		Expect(writer.Write(received)).To(Succeed())
+		Expect(writer.Close()).To(Succeed())
+
+		uploader := archiver.NewFakeUploader()
+		mgr := archiver.NewManager(root, time.Hour, 2, uploader)
+
+		Eventually(func() map[string][]byte {
+			mgr.Sweep(context.Background())
+			return uploader.Objects()
+		}, 10*time.Second).Should(HaveLen(1))
+
+		var found bool
+		for _, data := range uploader.Objects() {
+			gz, err := gzip.NewReader(bytes.NewReader(data))
+			Expect(err).NotTo(HaveOccurred())
+
+			out, err := io.ReadAll(gz)
+			Expect(err).NotTo(HaveOccurred())
+
+			if strings.Contains(string(out), "Archiver message") {
+				found = true
+			}
+		}
+
+		Expect(found).To(BeTrue())
+	})
+})
@@ -0,0 +1,53 @@
+package lats_test
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/plumbing/v2/jsonpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RLP Gateway", func() {
+	It("streams v2 log envelopes as server-sent events", func() {
+		env := createLogEnvelopeV2("Gateway stream message", "gateway-foo")
+		EmitToMetronV2(env)
+
+		url := fmt.Sprintf("http://%s/v2/read?source_id=%s&log", config.GatewayAddr, "gateway-foo")
+
+		var resp *http.Response
+		Eventually(func() error {
+			var err error
+			resp, err = http.Get(url)
+			return err
+		}, 10*time.Second).Should(Succeed())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+		reader := bufio.NewReader(resp.Body)
+
+		getPayload := func() (string, error) {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return "", err
+			}
+			if !strings.HasPrefix(line, "data: ") {
+				return "", nil
+			}
+
+			received, err := jsonpb.UnmarshalEnvelope([]byte(strings.TrimPrefix(line, "data: ")))
+			if err != nil {
+				return "", err
+			}
+			return string(received.GetLog().GetPayload()), nil
+		}
+
+		Eventually(getPayload, 5*time.Second).Should(Equal("Gateway stream message"))
+	})
+})
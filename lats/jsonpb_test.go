@@ -0,0 +1,48 @@
+package lats_test
+
+import (
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/plumbing/v2/jsonpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("jsonpb stability", func() {
+	It("round-trips a v1 log converted to v2 through RLP without losing data", func() {
+		msgChan := ReadFromRLP("jsonpb-v1-foo", false)
+
+		env := createLogEnvelopeV1("Stream message", "jsonpb-v1-foo")
+		EmitToMetronV1(env)
+
+		var outEnv *v2.Envelope
+		Eventually(msgChan, 5).Should(Receive(&outEnv))
+
+		data, err := jsonpb.MarshalEnvelope(outEnv)
+		Expect(err).NotTo(HaveOccurred())
+
+		roundTripped, err := jsonpb.UnmarshalEnvelope(data)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(roundTripped.GetSourceId()).To(Equal(outEnv.GetSourceId()))
+		Expect(roundTripped.GetLog()).To(Equal(outEnv.GetLog()))
+	})
+
+	It("round-trips a native v2 log through RLP without losing data", func() {
+		msgChan := ReadFromRLP("jsonpb-v2-foo", false)
+
+		env := createLogEnvelopeV2("Stream message", "jsonpb-v2-foo")
+		EmitToMetronV2(env)
+
+		var outEnv *v2.Envelope
+		Eventually(msgChan, 5).Should(Receive(&outEnv))
+
+		data, err := jsonpb.MarshalEnvelope(outEnv)
+		Expect(err).NotTo(HaveOccurred())
+
+		roundTripped, err := jsonpb.UnmarshalEnvelope(data)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(roundTripped.GetSourceId()).To(Equal(outEnv.GetSourceId()))
+		Expect(roundTripped.GetLog()).To(Equal(outEnv.GetLog()))
+	})
+})
@@ -0,0 +1,95 @@
+package lats_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/logger"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/plumbing/syslogbridge"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// readOctetCountedFrame reads a single RFC 6587 octet-counted frame
+// ("<byte-length> <message>") off r, the framing syslogbridge.Drain
+// implementations use for TCP/TLS transport. Unlike line-delimited
+// framing, there is no trailing newline to scan for.
+func readOctetCountedFrame(r *bufio.Reader) (string, error) {
+	lengthField, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+
+	length, err := strconv.Atoi(lengthField[:len(lengthField)-1])
+	if err != nil {
+		return "", fmt.Errorf("invalid octet count %q: %w", lengthField, err)
+	}
+
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return "", err
+	}
+
+	return string(msg), nil
+}
+
+// channelEnvelopeStream adapts the channel ReadFromRLP hands back into the
+// EnvelopeStream interface syslogbridge.Bridge reads from.
+type channelEnvelopeStream struct {
+	envelopes <-chan *v2.Envelope
+}
+
+func (c *channelEnvelopeStream) Recv() (*v2.EnvelopeBatch, error) {
+	e, ok := <-c.envelopes
+	if !ok {
+		return nil, io.EOF
+	}
+	return &v2.EnvelopeBatch{Batch: []*v2.Envelope{e}}, nil
+}
+
+var _ = Describe("Syslog Bridge", func() {
+	It("forwards a v2 log read from RLP to a syslog drain in RFC 5424 form", func() {
+		sink, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer sink.Close()
+
+		received := make(chan string, 1)
+		go func() {
+			conn, err := sink.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			frame, err := readOctetCountedFrame(bufio.NewReader(conn))
+			if err == nil {
+				received <- frame
+			}
+		}()
+
+		bridge, err := syslogbridge.NewBridge(
+			[]string{"syslog://" + sink.Addr().String()},
+			nil,
+			logger.NewText(logger.LevelError),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		msgChan := ReadFromRLP("syslog-bridge-foo", false)
+		go bridge.Run(&channelEnvelopeStream{envelopes: msgChan})
+
+		env := createLogEnvelopeV2("Syslog bridge message", "syslog-bridge-foo")
+		EmitToMetronV2(env)
+
+		var frame string
+		Eventually(received, 10*time.Second).Should(Receive(&frame))
+
+		Expect(frame).To(ContainSubstring("syslog-bridge-foo"))
+		Expect(frame).To(ContainSubstring("Syslog bridge message"))
+		Expect(frame).To(MatchRegexp(`^<\d+>1 `))
+	})
+})
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"time"
+
+	envstruct "code.cloudfoundry.org/go-envstruct"
+	"code.cloudfoundry.org/loggregator/logger"
+)
+
+// Config holds the configuration for the log-archiver.
+type Config struct {
+	LogsProviderAddr       string `env:"LOGS_PROVIDER_ADDR,        required, report"`
+	LogsProviderCAPath     string `env:"LOGS_PROVIDER_CA_PATH,     required, report"`
+	LogsProviderCertPath   string `env:"LOGS_PROVIDER_CERT_PATH,   required, report"`
+	LogsProviderKeyPath    string `env:"LOGS_PROVIDER_KEY_PATH,    required, report"`
+	LogsProviderCommonName string `env:"LOGS_PROVIDER_COMMON_NAME,           report"`
+
+	ArchiveRoot   string        `env:"ARCHIVE_ROOT,   required, report"`
+	SweepInterval time.Duration `env:"SWEEP_INTERVAL,           report"`
+
+	Bucket      string `env:"BUCKET,       required, report"`
+	Region      string `env:"REGION,       required, report"`
+	Endpoint    string `env:"ENDPOINT,               report"`
+	WorkerCount int    `env:"WORKER_COUNT,           report"`
+
+	LogFormat string `env:"LOG_FORMAT, report"`
+	LogLevel  string `env:"LOG_LEVEL,  report"`
+}
+
+// LoadConfig will load and return the config from the current environment.
+// If this fails this function will fatally log.
+func LoadConfig() Config {
+	cfg := Config{
+		LogsProviderCommonName: "reverselogproxy",
+		SweepInterval:          30 * time.Second,
+		WorkerCount:            4,
+		LogFormat:              "text",
+		LogLevel:               "info",
+	}
+
+	if err := envstruct.Load(&cfg); err != nil {
+		// LOG_FORMAT/LOG_LEVEL haven't been parsed yet, so fall back to the
+		// default text logger for this one fatal path.
+		logger.NewText(logger.LevelInfo).Fatalf("failed to load config from environment: %s", err)
+	}
+
+	envstruct.WriteReport(&cfg)
+
+	return cfg
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// jsonService emits one JSON object per line: level, ts, caller, msg, and
+// any fields merged in as top-level keys, suitable for ingestion by a log
+// pipeline.
+type jsonService struct {
+	level  Level
+	fields []Field
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSON returns a Service that writes a JSON object per log line to
+// stderr (or the writer supplied via WithWriter).
+func NewJSON(level Level, opts ...Option) Service {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &jsonService{
+		level: level,
+		w:     o.writer,
+	}
+}
+
+func (s *jsonService) Debug(msg string, fields ...Field) { s.emit(LevelDebug, msg, fields) }
+func (s *jsonService) Info(msg string, fields ...Field)  { s.emit(LevelInfo, msg, fields) }
+func (s *jsonService) Warn(msg string, fields ...Field)  { s.emit(LevelWarn, msg, fields) }
+func (s *jsonService) Error(msg string, fields ...Field) { s.emit(LevelError, msg, fields) }
+
+func (s *jsonService) Fatalf(format string, args ...interface{}) {
+	s.emit(LevelError, fmt.Sprintf(format, args...), nil)
+	osExit(1)
+}
+
+func (s *jsonService) With(fields ...Field) Service {
+	return &jsonService{
+		level:  s.level,
+		fields: append(append([]Field{}, s.fields...), fields...),
+		w:      s.w,
+	}
+}
+
+func (s *jsonService) emit(level Level, msg string, fields []Field) {
+	if level < s.level {
+		return
+	}
+
+	line := map[string]interface{}{
+		"level":  level.String(),
+		"ts":     time.Now().UTC().Format(time.RFC3339Nano),
+		"caller": caller(),
+		"msg":    msg,
+	}
+
+	for _, f := range s.fields {
+		line[f.Key] = f.Value
+	}
+	for _, f := range fields {
+		line[f.Key] = f.Value
+	}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.w, "%s\n", data)
+}
+
+func caller() string {
+	_, file, line, ok := runtime.Caller(3)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// osExit is a var so tests can stub out process termination.
+var osExit = os.Exit
@@ -0,0 +1,89 @@
+// Package logger provides a small structured-logging abstraction used by
+// the subsystems built on top of the reverse log proxy (the rlp-gateway,
+// log-archiver and syslog-bridge). Metron, doppler and the reverse log
+// proxy itself do not use it. It exists so call sites can emit leveled,
+// structured log lines without depending on a specific logging library,
+// and so tests can assert against log output.
+package logger
+
+// Service is the logging surface every component depends on. Call
+// New once per process and thread the returned Service through
+// constructors instead of reaching for the standard library "log"
+// package directly.
+type Service interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+
+	// Fatalf preserves the historical behavior of the "log" package:
+	// it logs the message and then terminates the process.
+	Fatalf(format string, args ...interface{})
+
+	// With returns a Service that includes fields on every subsequent
+	// log line, in addition to any passed at the call site.
+	With(fields ...Field) Service
+}
+
+// Field is a single structured key/value pair attached to a log line.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F builds a Field. It's a terse constructor because call sites tend to
+// pass several of these inline, e.g. log.Info("connected", F("addr", addr)).
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Level is a logging verbosity threshold.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String renders the level the way it appears in both built-in formats.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseLevel maps the LOG_LEVEL env var onto a Level, defaulting to
+// LevelInfo for an empty or unrecognized value.
+func ParseLevel(s string) Level {
+	switch s {
+	case "debug":
+		return LevelDebug
+	case "warn":
+		return LevelWarn
+	case "error":
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
+
+// New builds the Service configured by format ("text" or "json",
+// defaulting to "text") at the given level, writing to the process's
+// stderr.
+func New(format string, level Level) Service {
+	if format == "json" {
+		return NewJSON(level)
+	}
+	return NewText(level)
+}
@@ -0,0 +1,68 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"code.cloudfoundry.org/loggregator/logger"
+)
+
+func TestTextServiceFormatsFieldsAndRespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewText(logger.LevelWarn, logger.WithWriter(&buf))
+
+	log.Info("should be filtered out")
+	if buf.Len() != 0 {
+		t.Fatalf("expected info to be filtered at warn level, got %q", buf.String())
+	}
+
+	log.Error("boom", logger.F("source_id", "foo"))
+	if !strings.Contains(buf.String(), "ERROR boom source_id=foo") {
+		t.Fatalf("unexpected log line: %q", buf.String())
+	}
+}
+
+func TestJSONServiceEmitsStructuredFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewJSON(logger.LevelDebug, logger.WithWriter(&buf))
+
+	log.With(logger.F("component", "gateway")).Info("connected", logger.F("addr", "localhost:1234"))
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected valid JSON line, got %q: %s", buf.String(), err)
+	}
+
+	if line["msg"] != "connected" {
+		t.Fatalf("expected msg field, got %v", line)
+	}
+	if line["component"] != "gateway" {
+		t.Fatalf("expected With fields to be merged, got %v", line)
+	}
+	if line["addr"] != "localhost:1234" {
+		t.Fatalf("expected call-site fields to be merged, got %v", line)
+	}
+	if line["level"] != "info" {
+		t.Fatalf("expected level field, got %v", line)
+	}
+}
+
+func TestJSONServiceCallerPointsAtCallSite(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewJSON(logger.LevelDebug, logger.WithWriter(&buf))
+
+	log.Info("hello") // <-- this is the line caller should report
+	const wantLine = "logger_test.go:56"
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected valid JSON line, got %q: %s", buf.String(), err)
+	}
+
+	caller, _ := line["caller"].(string)
+	if !strings.HasSuffix(caller, wantLine) {
+		t.Fatalf("expected caller to point at %s, got %q", wantLine, caller)
+	}
+}
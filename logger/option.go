@@ -0,0 +1,23 @@
+package logger
+
+import (
+	"io"
+	"os"
+)
+
+type options struct {
+	writer io.Writer
+}
+
+func defaultOptions() options {
+	return options{writer: os.Stderr}
+}
+
+// Option configures a built-in Service implementation.
+type Option func(*options)
+
+// WithWriter overrides the destination for log lines. It defaults to
+// os.Stderr and is primarily useful in tests.
+func WithWriter(w io.Writer) Option {
+	return func(o *options) { o.writer = w }
+}
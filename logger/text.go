@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// textService preserves the plain stderr log lines loggregator components
+// emitted before structured logging was introduced: a timestamp prefix,
+// then the upper-cased level and the message, with any fields appended as
+// key=value pairs.
+type textService struct {
+	level  Level
+	fields []Field
+	log    *log.Logger
+}
+
+// NewText returns a Service that writes human-readable lines to stderr,
+// matching the format components used when they called the standard
+// library "log" package directly.
+func NewText(level Level, opts ...Option) Service {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &textService{
+		level: level,
+		log:   log.New(o.writer, "", log.LstdFlags),
+	}
+}
+
+func (s *textService) Debug(msg string, fields ...Field) { s.emit(LevelDebug, msg, fields) }
+func (s *textService) Info(msg string, fields ...Field)  { s.emit(LevelInfo, msg, fields) }
+func (s *textService) Warn(msg string, fields ...Field)  { s.emit(LevelWarn, msg, fields) }
+func (s *textService) Error(msg string, fields ...Field) { s.emit(LevelError, msg, fields) }
+
+func (s *textService) Fatalf(format string, args ...interface{}) {
+	s.log.Fatalf(format, args...)
+}
+
+func (s *textService) With(fields ...Field) Service {
+	return &textService{
+		level:  s.level,
+		fields: append(append([]Field{}, s.fields...), fields...),
+		log:    s.log,
+	}
+}
+
+func (s *textService) emit(level Level, msg string, fields []Field) {
+	if level < s.level {
+		return
+	}
+
+	all := append(append([]Field{}, s.fields...), fields...)
+
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(level.String()))
+	b.WriteString(" ")
+	b.WriteString(msg)
+
+	for _, f := range all {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+
+	s.log.Print(b.String())
+}
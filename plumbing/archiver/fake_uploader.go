@@ -0,0 +1,61 @@
+package archiver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FakeUploader is a test double for Uploader that copies uploaded file
+// contents into an in-memory bucket keyed by base file name.
+type FakeUploader struct {
+	mu     sync.Mutex
+	bucket map[string][]byte
+
+	// UploadErr, if set, is returned by Upload instead of performing the
+	// copy. It is reset to nil after being returned once.
+	UploadErr error
+}
+
+// NewFakeUploader returns an empty FakeUploader.
+func NewFakeUploader() *FakeUploader {
+	return &FakeUploader{
+		bucket: make(map[string][]byte),
+	}
+}
+
+// Upload implements Uploader.
+func (f *FakeUploader) Upload(ctx context.Context, path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.UploadErr != nil {
+		err := f.UploadErr
+		f.UploadErr = nil
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	f.bucket[filepath.Base(path)] = data
+
+	return nil
+}
+
+// Objects returns a snapshot of everything uploaded so far, keyed by base
+// file name.
+func (f *FakeUploader) Objects() map[string][]byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make(map[string][]byte, len(f.bucket))
+	for k, v := range f.bucket {
+		out[k] = v
+	}
+
+	return out
+}
@@ -0,0 +1,58 @@
+package archiver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Uploader uploads archive files to an S3-compatible bucket. It also
+// supports MinIO-style backends via an endpoint override.
+type S3Uploader struct {
+	bucket string
+	client *s3.Client
+}
+
+// NewS3Uploader builds an S3Uploader for bucket in region. If endpoint is
+// non-empty, the client is configured to talk to that endpoint instead of
+// AWS (e.g. a MinIO deployment) using path-style addressing.
+func NewS3Uploader(ctx context.Context, bucket, region, endpoint string) (*S3Uploader, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &S3Uploader{
+		bucket: bucket,
+		client: client,
+	}, nil
+}
+
+// Upload streams the file at path to the configured bucket, keyed by its
+// base name.
+func (u *S3Uploader) Upload(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = u.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(filepath.Base(path)),
+		Body:   f,
+	})
+
+	return err
+}
@@ -0,0 +1,166 @@
+package archiver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/logger"
+)
+
+// Manager periodically scans Root for completed segment files and hands
+// them off to a pool of workers for upload.
+type Manager struct {
+	root        string
+	interval    time.Duration
+	workerCount int
+	uploader    Uploader
+	log         logger.Service
+
+	maxRetries int
+	backoff    time.Duration
+}
+
+// ManagerOption configures a Manager.
+type ManagerOption func(*Manager)
+
+// WithManagerLogger sets the logger used for diagnostics.
+func WithManagerLogger(l logger.Service) ManagerOption {
+	return func(m *Manager) { m.log = l }
+}
+
+// WithRetryBackoff overrides the default retry count and initial backoff
+// duration used when an upload fails transiently. The backoff doubles
+// between attempts.
+func WithRetryBackoff(maxRetries int, initial time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.maxRetries = maxRetries
+		m.backoff = initial
+	}
+}
+
+// NewManager creates a Manager that sweeps root every interval using
+// workerCount concurrent uploaders.
+func NewManager(root string, interval time.Duration, workerCount int, uploader Uploader, opts ...ManagerOption) *Manager {
+	m := &Manager{
+		root:        root,
+		interval:    interval,
+		workerCount: workerCount,
+		uploader:    uploader,
+		log:         logger.NewText(logger.LevelInfo),
+		maxRetries:  3,
+		backoff:     time.Second,
+	}
+
+	for _, o := range opts {
+		o(m)
+	}
+
+	return m
+}
+
+// Run sweeps root on every tick of interval until ctx is cancelled.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep enqueues every completed segment file under root onto a bounded
+// work channel and waits for workerCount workers to drain it. It is called
+// automatically by Run on every tick, and exposed so callers (and tests)
+// can trigger a sweep on demand.
+func (m *Manager) Sweep(ctx context.Context) {
+	paths, err := m.completedSegments()
+	if err != nil {
+		m.log.Error("failed to scan archive root", logger.F("root", m.root), logger.F("error", err))
+		return
+	}
+
+	work := make(chan string, len(paths))
+	for _, p := range paths {
+		work <- p
+	}
+	close(work)
+
+	done := make(chan struct{})
+	for i := 0; i < m.workerCount; i++ {
+		go func() {
+			m.worker(ctx, work)
+			done <- struct{}{}
+		}()
+	}
+
+	for i := 0; i < m.workerCount; i++ {
+		<-done
+	}
+}
+
+func (m *Manager) worker(ctx context.Context, work <-chan string) {
+	for path := range work {
+		if err := m.uploadWithRetry(ctx, path); err != nil {
+			m.log.Error("failed to upload archive segment", logger.F("path", path), logger.F("error", err))
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			m.log.Error("failed to remove uploaded archive segment", logger.F("path", path), logger.F("error", err))
+		}
+	}
+}
+
+func (m *Manager) uploadWithRetry(ctx context.Context, path string) error {
+	backoff := m.backoff
+
+	var err error
+	for attempt := 0; attempt <= m.maxRetries; attempt++ {
+		if err = m.uploader.Upload(ctx, path); err == nil {
+			return nil
+		}
+
+		if attempt == m.maxRetries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	return err
+}
+
+// completedSegments returns the paths of every segment file under root
+// that has finished rotating, i.e. does not carry the writingSuffix.
+func (m *Manager) completedSegments() ([]string, error) {
+	entries, err := os.ReadDir(m.root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() || strings.HasSuffix(e.Name(), writingSuffix) {
+			continue
+		}
+		paths = append(paths, filepath.Join(m.root, e.Name()))
+	}
+
+	return paths, nil
+}
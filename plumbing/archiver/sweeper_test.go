@@ -0,0 +1,86 @@
+package archiver_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/plumbing/archiver"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+func TestSweeperUploadsCompletedSegmentsAndDeletesThem(t *testing.T) {
+	root, err := os.MkdirTemp("", "archiver-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	w := archiver.NewWriter(root, archiver.WithMaxBytes(1<<30), archiver.WithMaxAge(time.Hour))
+	if err := w.Write(&v2.Envelope{SourceId: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	uploader := archiver.NewFakeUploader()
+	mgr := archiver.NewManager(root, time.Hour, 2, uploader)
+	mgr.Sweep(context.Background())
+
+	objects := uploader.Objects()
+	if len(objects) != 1 {
+		t.Fatalf("expected 1 uploaded object, got %d", len(objects))
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected uploaded segment to be removed, found %d entries", len(entries))
+	}
+
+	for _, data := range objects {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := io.ReadAll(gz)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(out) == 0 {
+			t.Fatal("expected non-empty decompressed segment")
+		}
+	}
+}
+
+func TestSweeperIgnoresInProgressSegments(t *testing.T) {
+	root, err := os.MkdirTemp("", "archiver-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	w := archiver.NewWriter(root, archiver.WithMaxBytes(1<<30), archiver.WithMaxAge(time.Hour))
+	if err := w.Write(&v2.Envelope{SourceId: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	// Deliberately not closed: the segment should still carry its
+	// ".writing" suffix and be left alone by the sweeper.
+
+	uploader := archiver.NewFakeUploader()
+	mgr := archiver.NewManager(root, time.Hour, 2, uploader)
+	mgr.Sweep(context.Background())
+
+	if len(uploader.Objects()) != 0 {
+		t.Fatalf("expected no uploads for an in-progress segment")
+	}
+
+	w.Close()
+}
@@ -0,0 +1,9 @@
+package archiver
+
+import "context"
+
+// Uploader pushes a single archive file to an object store. Implementations
+// must be safe for concurrent use by multiple sweep workers.
+type Uploader interface {
+	Upload(ctx context.Context, path string) error
+}
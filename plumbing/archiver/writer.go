@@ -0,0 +1,176 @@
+// Package archiver batches the v2 envelope firehose into rotated,
+// gzip-compressed newline-delimited JSON files on disk, and periodically
+// sweeps completed files off to an S3-compatible object store.
+package archiver
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+// writingSuffix marks a segment that is still being appended to. The
+// sweeper ignores any file with this suffix so it never uploads a segment
+// out from under the Writer.
+const writingSuffix = ".writing"
+
+// EnvelopeStream is satisfied by the gRPC stream returned when opening a
+// firehose read against the upstream RLP.
+type EnvelopeStream interface {
+	Recv() (*v2.EnvelopeBatch, error)
+}
+
+// Writer subscribes to an EnvelopeStream and appends each envelope as a
+// line of JSON to a gzip'd segment file under Root, rotating to a new
+// segment once the current one exceeds MaxBytes or MaxAge.
+type Writer struct {
+	root     string
+	maxBytes int64
+	maxAge   time.Duration
+
+	mu       sync.Mutex
+	f        *os.File
+	gz       *gzip.Writer
+	path     string
+	size     int64
+	openedAt time.Time
+}
+
+// WriterOption configures a Writer.
+type WriterOption func(*Writer)
+
+// WithMaxBytes overrides the default segment size rotation threshold.
+func WithMaxBytes(n int64) WriterOption {
+	return func(w *Writer) { w.maxBytes = n }
+}
+
+// WithMaxAge overrides the default segment age rotation threshold.
+func WithMaxAge(d time.Duration) WriterOption {
+	return func(w *Writer) { w.maxAge = d }
+}
+
+// NewWriter creates a Writer that rotates segment files under root.
+func NewWriter(root string, opts ...WriterOption) *Writer {
+	w := &Writer{
+		root:     root,
+		maxBytes: 64 * 1024 * 1024,
+		maxAge:   5 * time.Minute,
+	}
+
+	for _, o := range opts {
+		o(w)
+	}
+
+	return w
+}
+
+// Run reads batches from stream until it returns an error, writing every
+// envelope to disk. It returns the error from the stream.
+func (w *Writer) Run(stream EnvelopeStream) error {
+	for {
+		batch, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		for _, e := range batch.Batch {
+			if err := w.Write(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Write appends e to the current segment, rotating first if necessary.
+func (w *Writer) Write(e *v2.Envelope) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil || w.size >= w.maxBytes || time.Since(w.openedAt) >= w.maxAge {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	n, err := w.gz.Write(line)
+	if err != nil {
+		return err
+	}
+	w.size += int64(n)
+
+	return nil
+}
+
+// Close flushes and finalizes the current segment, if any.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.closeCurrent()
+}
+
+// rotate closes the current segment (renaming it to its final name) and
+// opens a new one. The caller must hold w.mu.
+func (w *Writer) rotate() error {
+	if err := w.closeCurrent(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(w.root, 0755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("segment-%d.ndjson.gz", time.Now().UnixNano())
+	path := filepath.Join(w.root, name+writingSuffix)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w.f = f
+	w.gz = gzip.NewWriter(f)
+	w.path = path
+	w.size = 0
+	w.openedAt = time.Now()
+
+	return nil
+}
+
+// closeCurrent finalizes the in-progress segment, renaming it so the
+// sweeper will pick it up. The caller must hold w.mu.
+func (w *Writer) closeCurrent() error {
+	if w.f == nil {
+		return nil
+	}
+
+	if err := w.gz.Close(); err != nil {
+		return err
+	}
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+
+	finalPath := w.path[:len(w.path)-len(writingSuffix)]
+	if err := os.Rename(w.path, finalPath); err != nil {
+		return err
+	}
+
+	w.f = nil
+	w.gz = nil
+	w.path = ""
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package syslogbridge
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/logger"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+// queueSize bounds how many envelopes can queue for a single drain before
+// the bridge starts dropping messages bound for it.
+const queueSize = 64
+
+// EnvelopeStream is satisfied by the gRPC stream returned when opening a
+// firehose read against the upstream RLP.
+type EnvelopeStream interface {
+	Recv() (*v2.EnvelopeBatch, error)
+}
+
+// Bridge forwards every v2 Log envelope it reads from an EnvelopeStream to
+// one or more syslog drains.
+type Bridge struct {
+	workers []*drainWorker
+	log     logger.Service
+}
+
+// NewBridge builds a Bridge that forwards to a Drain for every URL in
+// drainURLs, dialing connections with dialer.
+func NewBridge(drainURLs []string, dialer Dialer, log logger.Service) (*Bridge, error) {
+	b := &Bridge{log: log}
+
+	if dialer == nil {
+		dialer = netDialer{}
+	}
+
+	for _, u := range drainURLs {
+		drain, err := NewDrain(u, dialer)
+		if err != nil {
+			return nil, err
+		}
+
+		w := &drainWorker{
+			url:   u,
+			drain: drain,
+			queue: make(chan *v2.Envelope, queueSize),
+			log:   log,
+		}
+		go w.run()
+
+		b.workers = append(b.workers, w)
+	}
+
+	return b, nil
+}
+
+// Run reads batches from stream until it returns an error, forwarding
+// every log envelope to every configured drain. It returns the error from
+// the stream.
+func (b *Bridge) Run(stream EnvelopeStream) error {
+	for {
+		batch, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		for _, e := range batch.Batch {
+			b.Forward(e)
+		}
+	}
+}
+
+// Forward enqueues e onto every drain's queue, dropping (and counting the
+// drop) for any drain whose queue is full rather than blocking the rest of
+// the pipeline on a slow drain.
+func (b *Bridge) Forward(e *v2.Envelope) {
+	if e.GetLog() == nil {
+		return
+	}
+
+	for _, w := range b.workers {
+		select {
+		case w.queue <- e:
+		default:
+			atomic.AddInt64(&w.dropped, 1)
+		}
+	}
+}
+
+// drainWorker owns a single Drain and the goroutine draining its queue.
+type drainWorker struct {
+	url     string
+	drain   Drain
+	queue   chan *v2.Envelope
+	dropped int64
+	log     logger.Service
+}
+
+func (w *drainWorker) run() {
+	for e := range w.queue {
+		if dropped := atomic.SwapInt64(&w.dropped, 0); dropped > 0 {
+			w.write(diagnosticEnvelope(e.GetSourceId(), dropped))
+		}
+
+		w.write(e)
+	}
+}
+
+func (w *drainWorker) write(e *v2.Envelope) {
+	if err := w.drain.Write([]byte(FormatRFC5424(e))); err != nil {
+		w.log.Error("failed to write to syslog drain", logger.F("url", w.url), logger.F("error", err))
+	}
+}
+
+// diagnosticEnvelope is the synthetic "LGR" log noting how many messages a
+// slow drain forced the bridge to drop, mirroring the truncating-buffer
+// pattern doppler uses elsewhere. A drainWorker writes it only to its own
+// drain, ahead of the next message; it is not sent back into the
+// loggregator pipeline.
+func diagnosticEnvelope(sourceID string, dropped int64) *v2.Envelope {
+	return &v2.Envelope{
+		SourceId:  "LGR",
+		Timestamp: time.Now().UnixNano(),
+		Message: &v2.Envelope_Log{
+			Log: &v2.Log{
+				Payload: []byte(fmt.Sprintf("syslog-bridge: dropped %d messages for source %s, drain is too slow", dropped, sourceID)),
+				Type:    v2.Log_ERR,
+			},
+		},
+	}
+}
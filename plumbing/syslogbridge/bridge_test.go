@@ -0,0 +1,149 @@
+package syslogbridge_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/logger"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/plumbing/syslogbridge"
+)
+
+type fakeConn struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (f *fakeConn) Write(b []byte) (int, error) { return f.buf.Write(b) }
+func (f *fakeConn) Close() error                { return nil }
+
+type fakeDialer struct {
+	conn *fakeConn
+}
+
+func (d *fakeDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.conn, nil
+}
+
+func (d *fakeDialer) DialTLS(network, addr string, config *tls.Config) (net.Conn, error) {
+	return d.conn, nil
+}
+
+type fakeStream struct {
+	batches chan *v2.EnvelopeBatch
+}
+
+func (f *fakeStream) Recv() (*v2.EnvelopeBatch, error) {
+	b, ok := <-f.batches
+	if !ok {
+		return nil, io.EOF
+	}
+	return b, nil
+}
+
+func TestBridgeForwardsLogEnvelopesInRFC5424Form(t *testing.T) {
+	conn := &fakeConn{}
+	dialer := &fakeDialer{conn: conn}
+
+	bridge, err := syslogbridge.NewBridge(
+		[]string{"syslog-tls://drain.example.com:6514"},
+		dialer,
+		logger.NewText(logger.LevelError),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := &fakeStream{batches: make(chan *v2.EnvelopeBatch, 1)}
+	stream.batches <- &v2.EnvelopeBatch{
+		Batch: []*v2.Envelope{
+			{
+				SourceId: "foo",
+				Message: &v2.Envelope_Log{
+					Log: &v2.Log{Payload: []byte("hello"), Type: v2.Log_OUT},
+				},
+			},
+		},
+	}
+	close(stream.batches)
+
+	if err := bridge.Run(stream); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	var written string
+	for i := 0; i < 100; i++ {
+		written = conn.buf.String()
+		if written != "" {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if !strings.Contains(written, "<14>1 ") {
+		t.Fatalf("expected RFC5424 priority for user.info, got %q", written)
+	}
+	if !strings.Contains(written, "foo") {
+		t.Fatalf("expected APP-NAME foo in frame, got %q", written)
+	}
+	if !strings.Contains(written, "hello") {
+		t.Fatalf("expected payload in frame, got %q", written)
+	}
+}
+
+func TestBridgeSendsUnframedBodyToHTTPDrain(t *testing.T) {
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+	}))
+	defer server.Close()
+
+	bridge, err := syslogbridge.NewBridge(
+		[]string{server.URL},
+		nil,
+		logger.NewText(logger.LevelError),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := &fakeStream{batches: make(chan *v2.EnvelopeBatch, 1)}
+	stream.batches <- &v2.EnvelopeBatch{
+		Batch: []*v2.Envelope{
+			{
+				SourceId: "foo",
+				Message: &v2.Envelope_Log{
+					Log: &v2.Log{Payload: []byte("hello"), Type: v2.Log_OUT},
+				},
+			},
+		},
+	}
+	close(stream.batches)
+
+	if err := bridge.Run(stream); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	var body string
+	select {
+	case body = <-bodies:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for HTTP drain request")
+	}
+
+	if regexp.MustCompile(`^\d+ <`).MatchString(body) {
+		t.Fatalf("expected bare RFC5424 body with no octet-count prefix, got %q", body)
+	}
+	if !strings.HasPrefix(body, "<14>1 ") {
+		t.Fatalf("expected RFC5424 priority for user.info, got %q", body)
+	}
+}
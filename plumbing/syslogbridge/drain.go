@@ -0,0 +1,141 @@
+package syslogbridge
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Drain accepts a single RFC 5424 syslog message for a configured drain
+// URL. Each implementation applies whatever framing its own transport
+// requires.
+type Drain interface {
+	Write(msg []byte) error
+	Close() error
+}
+
+// Dialer opens the network connections drains write to. Production code
+// uses netDialer; tests substitute a fake so they don't need a real
+// listener.
+type Dialer interface {
+	Dial(network, addr string) (net.Conn, error)
+	DialTLS(network, addr string, config *tls.Config) (net.Conn, error)
+}
+
+// netDialer is the default Dialer, backed by the standard library.
+type netDialer struct{}
+
+func (netDialer) Dial(network, addr string) (net.Conn, error) {
+	return net.DialTimeout(network, addr, 5*time.Second)
+}
+
+func (netDialer) DialTLS(network, addr string, config *tls.Config) (net.Conn, error) {
+	return tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, network, addr, config)
+}
+
+// NewDrain builds the Drain implementation appropriate for rawURL's
+// scheme: "syslog" dials plain TCP, "syslog-tls" dials TLS, and "https"
+// POSTs each message as a bare RFC 5424 request body.
+func NewDrain(rawURL string, dialer Dialer) (Drain, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid drain url %q: %w", rawURL, err)
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "syslog":
+		return newTCPDrain(u.Host, dialer, false)
+	case "syslog-tls":
+		return newTCPDrain(u.Host, dialer, true)
+	case "https":
+		return newHTTPDrain(rawURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported drain scheme %q", u.Scheme)
+	}
+}
+
+// tcpDrain writes RFC 6587 octet-counted frames over a plain or TLS TCP
+// connection, redialing lazily if the connection has never been
+// established or was previously closed by the peer. Octet-counting is
+// scoped to this stream transport, where a delimiter-based framing would
+// otherwise be ambiguous with message content.
+type tcpDrain struct {
+	addr   string
+	tls    bool
+	dialer Dialer
+	conn   net.Conn
+}
+
+func newTCPDrain(addr string, dialer Dialer, useTLS bool) (*tcpDrain, error) {
+	return &tcpDrain{addr: addr, tls: useTLS, dialer: dialer}, nil
+}
+
+func (d *tcpDrain) Write(msg []byte) error {
+	if d.conn == nil {
+		conn, err := d.dial()
+		if err != nil {
+			return err
+		}
+		d.conn = conn
+	}
+
+	if _, err := d.conn.Write(FrameOctetCounted(string(msg))); err != nil {
+		d.conn.Close()
+		d.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+func (d *tcpDrain) dial() (net.Conn, error) {
+	if d.tls {
+		return d.dialer.DialTLS("tcp", d.addr, &tls.Config{})
+	}
+	return d.dialer.Dial("tcp", d.addr)
+}
+
+func (d *tcpDrain) Close() error {
+	if d.conn == nil {
+		return nil
+	}
+	return d.conn.Close()
+}
+
+// httpDrain POSTs each message individually to an HTTP(S) drain endpoint
+// as a bare RFC 5424 body; HTTP's own framing (Content-Length) makes the
+// TCP/TLS octet-counting unnecessary here.
+type httpDrain struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPDrain(url string) *httpDrain {
+	return &httpDrain{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (d *httpDrain) Write(msg []byte) error {
+	resp, err := d.client.Post(d.url, "application/octet-stream", strings.NewReader(string(msg)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("drain %s responded with status %d", d.url, resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (d *httpDrain) Close() error {
+	d.client.CloseIdleConnections()
+	return nil
+}
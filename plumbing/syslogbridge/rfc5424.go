@@ -0,0 +1,62 @@
+package syslogbridge
+
+import (
+	"fmt"
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+// facilityUser is syslog facility 1 ("user-level messages"), per RFC 5424
+// section 6.2.1.
+const facilityUser = 1
+
+// severityInfo and severityError map Log.Type OUT/ERR onto the RFC 5424
+// severities drains expect for stdout/stderr.
+const (
+	severityInfo  = 6
+	severityError = 3
+)
+
+// FormatRFC5424 renders a v2 Log envelope as an RFC 5424 syslog message,
+// mapping SourceId to APP-NAME, the "instance_id" tag to PROCID, and
+// Log.Type to severity under the user facility.
+func FormatRFC5424(e *v2.Envelope) string {
+	pri := facilityUser*8 + severity(e.GetLog())
+
+	ts := time.Unix(0, e.GetTimestamp()).UTC().Format(time.RFC3339Nano)
+
+	appName := e.GetSourceId()
+	if appName == "" {
+		appName = "-"
+	}
+
+	procID := instanceID(e)
+
+	return fmt.Sprintf("<%d>1 %s - %s %s - - %s", pri, ts, appName, procID, e.GetLog().GetPayload())
+}
+
+// FrameOctetCounted prepends the octet count TCP/TLS transport framing
+// (RFC 6587 section 3.4.1) expects: "<byte-length> <message>".
+func FrameOctetCounted(msg string) []byte {
+	return []byte(fmt.Sprintf("%d %s", len(msg), msg))
+}
+
+func severity(log *v2.Log) int {
+	if log.GetType() == v2.Log_ERR {
+		return severityError
+	}
+	return severityInfo
+}
+
+func instanceID(e *v2.Envelope) string {
+	if tag := e.GetTags()["instance_id"]; tag != "" {
+		return tag
+	}
+	if v, ok := e.GetDeprecatedTags()["instance_id"]; ok {
+		if text := v.GetText(); text != "" {
+			return text
+		}
+	}
+	return "-"
+}
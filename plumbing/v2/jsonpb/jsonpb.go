@@ -0,0 +1,305 @@
+// Package jsonpb provides a canonical, stable JSON encoding for
+// loggregator_v2 envelopes, for use on the wire by HTTP clients (e.g. the
+// RLP gateway) that shouldn't have to depend on protobuf's own JSON
+// mapping or Go struct tags, which are free to change with the generated
+// code.
+package jsonpb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+// envelope is the stable wire representation of a v2.Envelope.
+type envelope struct {
+	SourceID   string         `json:"source_id"`
+	InstanceID string         `json:"instance_id,omitempty"`
+	Timestamp  string         `json:"timestamp"`
+	Tags       map[string]tag `json:"tags,omitempty"`
+	Message    message        `json:"message"`
+}
+
+// tag is a discriminated union over the tag value types v2.Value
+// supports.
+type tag struct {
+	Text    *string  `json:"text,omitempty"`
+	Integer *int64   `json:"integer,omitempty"`
+	Decimal *float64 `json:"decimal,omitempty"`
+}
+
+// message is a discriminated union over the envelope's message types.
+// At most one field is populated; none is for an envelope without a
+// message.
+type message struct {
+	Log     *logMessage     `json:"log,omitempty"`
+	Counter *counterMessage `json:"counter,omitempty"`
+	Gauge   *gaugeMessage   `json:"gauge,omitempty"`
+	Timer   *timerMessage   `json:"timer,omitempty"`
+	Event   *eventMessage   `json:"event,omitempty"`
+}
+
+type logMessage struct {
+	Payload string `json:"payload"` // base64-encoded
+	Type    string `json:"type"`    // "out" or "err"
+}
+
+type counterMessage struct {
+	Name  string  `json:"name"`
+	Delta *uint64 `json:"delta,omitempty"`
+	Total uint64  `json:"total"`
+}
+
+type gaugeMessage struct {
+	Metrics map[string]gaugeValue `json:"metrics"`
+}
+
+type gaugeValue struct {
+	Unit  string  `json:"unit"`
+	Value float64 `json:"value"`
+}
+
+type timerMessage struct {
+	Name  string `json:"name"`
+	Start int64  `json:"start"`
+	Stop  int64  `json:"stop"`
+}
+
+type eventMessage struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// batch is the stable wire representation of a v2.EnvelopeBatch.
+type batch struct {
+	Batch []envelope `json:"batch"`
+}
+
+// MarshalEnvelope renders e in the stable JSON form.
+func MarshalEnvelope(e *v2.Envelope) ([]byte, error) {
+	return json.Marshal(toWire(e))
+}
+
+// UnmarshalEnvelope parses the stable JSON form back into a v2.Envelope.
+func UnmarshalEnvelope(data []byte) (*v2.Envelope, error) {
+	var w envelope
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, err
+	}
+	return fromWire(w)
+}
+
+// MarshalEnvelopeBatch renders b in the stable JSON form.
+func MarshalEnvelopeBatch(b *v2.EnvelopeBatch) ([]byte, error) {
+	wb := batch{Batch: make([]envelope, 0, len(b.GetBatch()))}
+	for _, e := range b.GetBatch() {
+		wb.Batch = append(wb.Batch, toWire(e))
+	}
+	return json.Marshal(wb)
+}
+
+// UnmarshalEnvelopeBatch parses the stable JSON form back into a
+// v2.EnvelopeBatch.
+func UnmarshalEnvelopeBatch(data []byte) (*v2.EnvelopeBatch, error) {
+	var wb batch
+	if err := json.Unmarshal(data, &wb); err != nil {
+		return nil, err
+	}
+
+	out := &v2.EnvelopeBatch{}
+	for _, w := range wb.Batch {
+		e, err := fromWire(w)
+		if err != nil {
+			return nil, err
+		}
+		out.Batch = append(out.Batch, e)
+	}
+
+	return out, nil
+}
+
+func toWire(e *v2.Envelope) envelope {
+	w := envelope{
+		SourceID:  e.GetSourceId(),
+		Timestamp: time.Unix(0, e.GetTimestamp()).UTC().Format(time.RFC3339Nano),
+		Tags:      mergeTags(e),
+	}
+
+	switch {
+	case e.GetTags()["instance_id"] != "":
+		w.InstanceID = e.GetTags()["instance_id"]
+	case e.GetDeprecatedTags()["instance_id"] != nil:
+		w.InstanceID = e.GetDeprecatedTags()["instance_id"].GetText()
+	default:
+		w.InstanceID = e.GetInstanceId()
+	}
+
+	switch m := e.GetMessage().(type) {
+	case *v2.Envelope_Log:
+		w.Message.Log = &logMessage{
+			Payload: base64.StdEncoding.EncodeToString(m.Log.GetPayload()),
+			Type:    logTypeToWire(m.Log.GetType()),
+		}
+	case *v2.Envelope_Counter:
+		w.Message.Counter = &counterMessage{
+			Name:  m.Counter.GetName(),
+			Total: m.Counter.GetTotal(),
+		}
+		if m.Counter.GetDelta() != 0 {
+			d := m.Counter.GetDelta()
+			w.Message.Counter.Delta = &d
+		}
+	case *v2.Envelope_Gauge:
+		metrics := make(map[string]gaugeValue, len(m.Gauge.GetMetrics()))
+		for name, v := range m.Gauge.GetMetrics() {
+			metrics[name] = gaugeValue{Unit: v.GetUnit(), Value: v.GetValue()}
+		}
+		w.Message.Gauge = &gaugeMessage{Metrics: metrics}
+	case *v2.Envelope_Timer:
+		w.Message.Timer = &timerMessage{
+			Name:  m.Timer.GetName(),
+			Start: m.Timer.GetStart(),
+			Stop:  m.Timer.GetStop(),
+		}
+	case *v2.Envelope_Event:
+		w.Message.Event = &eventMessage{
+			Title: m.Event.GetTitle(),
+			Body:  m.Event.GetBody(),
+		}
+	}
+
+	return w
+}
+
+func fromWire(w envelope) (*v2.Envelope, error) {
+	ts, err := time.Parse(time.RFC3339Nano, w.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q: %w", w.Timestamp, err)
+	}
+
+	e := &v2.Envelope{
+		SourceId:  w.SourceID,
+		Timestamp: ts.UnixNano(),
+	}
+
+	if w.InstanceID != "" {
+		e.InstanceId = w.InstanceID
+	}
+
+	if len(w.Tags) > 0 {
+		e.Tags = make(map[string]string, len(w.Tags))
+		for k, v := range w.Tags {
+			switch {
+			case v.Text != nil:
+				e.Tags[k] = *v.Text
+			case v.Integer != nil:
+				e.Tags[k] = fmt.Sprintf("%d", *v.Integer)
+			case v.Decimal != nil:
+				e.Tags[k] = fmt.Sprintf("%v", *v.Decimal)
+			}
+		}
+	}
+
+	switch {
+	case w.Message.Log != nil:
+		payload, err := base64.StdEncoding.DecodeString(w.Message.Log.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log payload: %w", err)
+		}
+		e.Message = &v2.Envelope_Log{
+			Log: &v2.Log{
+				Payload: payload,
+				Type:    logTypeFromWire(w.Message.Log.Type),
+			},
+		}
+	case w.Message.Counter != nil:
+		c := &v2.Counter{
+			Name:  w.Message.Counter.Name,
+			Total: w.Message.Counter.Total,
+		}
+		if w.Message.Counter.Delta != nil {
+			c.Delta = *w.Message.Counter.Delta
+		}
+		e.Message = &v2.Envelope_Counter{Counter: c}
+	case w.Message.Gauge != nil:
+		metrics := make(map[string]*v2.GaugeValue, len(w.Message.Gauge.Metrics))
+		for name, v := range w.Message.Gauge.Metrics {
+			metrics[name] = &v2.GaugeValue{Unit: v.Unit, Value: v.Value}
+		}
+		e.Message = &v2.Envelope_Gauge{Gauge: &v2.Gauge{Metrics: metrics}}
+	case w.Message.Timer != nil:
+		e.Message = &v2.Envelope_Timer{
+			Timer: &v2.Timer{
+				Name:  w.Message.Timer.Name,
+				Start: w.Message.Timer.Start,
+				Stop:  w.Message.Timer.Stop,
+			},
+		}
+	case w.Message.Event != nil:
+		e.Message = &v2.Envelope_Event{
+			Event: &v2.Event{
+				Title: w.Message.Event.Title,
+				Body:  w.Message.Event.Body,
+			},
+		}
+	}
+
+	return e, nil
+}
+
+// mergeTags combines the current Tags field with the legacy DeprecatedTags
+// field into a single typed map, with Tags (plain strings) taking
+// precedence over a DeprecatedTags entry of the same name. "instance_id"
+// is excluded since it is lifted out to the envelope's top-level
+// instance_id field instead (see toWire), and must not appear in both
+// places.
+func mergeTags(e *v2.Envelope) map[string]tag {
+	merged := make(map[string]tag, len(e.GetTags())+len(e.GetDeprecatedTags()))
+
+	for name, v := range e.GetDeprecatedTags() {
+		merged[name] = tagFromValue(v)
+	}
+
+	for name, s := range e.GetTags() {
+		text := s
+		merged[name] = tag{Text: &text}
+	}
+
+	delete(merged, "instance_id")
+
+	if len(merged) == 0 {
+		return nil
+	}
+
+	return merged
+}
+
+func tagFromValue(v *v2.Value) tag {
+	switch d := v.GetData().(type) {
+	case *v2.Value_Text:
+		return tag{Text: &d.Text}
+	case *v2.Value_Integer:
+		return tag{Integer: &d.Integer}
+	case *v2.Value_Decimal:
+		return tag{Decimal: &d.Decimal}
+	default:
+		return tag{}
+	}
+}
+
+func logTypeToWire(t v2.Log_Type) string {
+	if t == v2.Log_ERR {
+		return "err"
+	}
+	return "out"
+}
+
+func logTypeFromWire(s string) v2.Log_Type {
+	if s == "err" {
+		return v2.Log_ERR
+	}
+	return v2.Log_OUT
+}
@@ -0,0 +1,176 @@
+package jsonpb_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/plumbing/v2/jsonpb"
+)
+
+func TestMarshalEnvelopeUsesStableFieldNames(t *testing.T) {
+	env := &v2.Envelope{
+		SourceId:  "foo",
+		Timestamp: 1000000000,
+		Tags:      map[string]string{"instance_id": "2"},
+		DeprecatedTags: map[string]*v2.Value{
+			"origin": {Data: &v2.Value_Text{Text: "bar"}},
+		},
+		Message: &v2.Envelope_Log{
+			Log: &v2.Log{Payload: []byte("hello"), Type: v2.Log_OUT},
+		},
+	}
+
+	data, err := jsonpb.MarshalEnvelope(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+
+	if raw["source_id"] != "foo" {
+		t.Fatalf("expected source_id field, got %v", raw)
+	}
+	if raw["timestamp"] != "1970-01-01T00:00:01Z" {
+		t.Fatalf("expected RFC3339Nano timestamp, got %v", raw["timestamp"])
+	}
+	if raw["instance_id"] != "2" {
+		t.Fatalf("expected instance_id lifted from tags, got %v", raw)
+	}
+
+	tags, ok := raw["tags"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected tags object, got %v", raw["tags"])
+	}
+	origin, ok := tags["origin"].(map[string]interface{})
+	if !ok || origin["text"] != "bar" {
+		t.Fatalf("expected origin tag as typed text, got %v", tags["origin"])
+	}
+
+	message, ok := raw["message"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected message object")
+	}
+	log, ok := message["log"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected discriminated log message")
+	}
+	if log["payload"] != "aGVsbG8=" {
+		t.Fatalf("expected base64 payload, got %v", log["payload"])
+	}
+	if log["type"] != "out" {
+		t.Fatalf("expected log type out, got %v", log["type"])
+	}
+}
+
+func TestRoundTripPreservesEnvelope(t *testing.T) {
+	original := &v2.Envelope{
+		SourceId:  "foo",
+		Timestamp: 1234567890,
+		Tags:      map[string]string{"instance_id": "3"},
+		Message: &v2.Envelope_Counter{
+			Counter: &v2.Counter{Name: "requests", Total: 42},
+		},
+	}
+
+	data, err := jsonpb.MarshalEnvelope(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := jsonpb.UnmarshalEnvelope(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.GetSourceId() != original.GetSourceId() {
+		t.Fatalf("source id mismatch: %q != %q", out.GetSourceId(), original.GetSourceId())
+	}
+	if out.GetTimestamp() != original.GetTimestamp() {
+		t.Fatalf("timestamp mismatch: %d != %d", out.GetTimestamp(), original.GetTimestamp())
+	}
+	if out.GetCounter().GetName() != "requests" || out.GetCounter().GetTotal() != 42 {
+		t.Fatalf("counter mismatch: %+v", out.GetCounter())
+	}
+}
+
+func TestRoundTripInstanceIDIsNotDuplicatedInTags(t *testing.T) {
+	original := &v2.Envelope{
+		SourceId:  "foo",
+		Timestamp: 1234567890,
+		Tags:      map[string]string{"instance_id": "3"},
+		Message: &v2.Envelope_Counter{
+			Counter: &v2.Counter{Name: "requests", Total: 42},
+		},
+	}
+
+	data, err := jsonpb.MarshalEnvelope(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := jsonpb.UnmarshalEnvelope(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.GetInstanceId() != "3" {
+		t.Fatalf("expected instance_id to round-trip, got %q", out.GetInstanceId())
+	}
+	if len(out.GetTags()) != 0 {
+		t.Fatalf("expected instance_id not to be duplicated into tags, got %+v", out.GetTags())
+	}
+}
+
+func TestRoundTripTaglessEnvelopeHasNilTags(t *testing.T) {
+	original := &v2.Envelope{
+		SourceId:  "foo",
+		Timestamp: 1234567890,
+		Message: &v2.Envelope_Counter{
+			Counter: &v2.Counter{Name: "requests", Total: 42},
+		},
+	}
+
+	data, err := jsonpb.MarshalEnvelope(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := jsonpb.UnmarshalEnvelope(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.GetTags() != nil {
+		t.Fatalf("expected nil tags for a tag-less envelope, got %+v", out.GetTags())
+	}
+}
+
+func TestMarshalEnvelopeBatch(t *testing.T) {
+	batch := &v2.EnvelopeBatch{
+		Batch: []*v2.Envelope{
+			{SourceId: "a"},
+			{SourceId: "b"},
+		},
+	}
+
+	data, err := jsonpb.MarshalEnvelopeBatch(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := jsonpb.UnmarshalEnvelopeBatch(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out.GetBatch()) != 2 {
+		t.Fatalf("expected 2 envelopes, got %d", len(out.GetBatch()))
+	}
+	if out.GetBatch()[0].GetSourceId() != "a" || out.GetBatch()[1].GetSourceId() != "b" {
+		t.Fatalf("unexpected batch contents: %+v", out.GetBatch())
+	}
+}
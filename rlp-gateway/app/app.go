@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/loggregator/logger"
+	"code.cloudfoundry.org/loggregator/plumbing"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/rlpgateway"
+	"google.golang.org/grpc"
+)
+
+// App wires together the gRPC connection to the upstream RLP and the HTTP
+// gateway that fronts it.
+type App struct {
+	cfg Config
+	log logger.Service
+
+	gateway *rlpgateway.Gateway
+}
+
+// NewApp returns an App configured from cfg, logging through log. No
+// upstream gRPC connection is made here: each client request to the
+// gateway dials its own connection, and connections are not shared
+// between requests.
+func NewApp(cfg Config, log logger.Service) *App {
+	a := &App{cfg: cfg, log: log}
+
+	a.gateway = rlpgateway.NewGateway(a.receive, cfg.GatewayAddr, rlpgateway.WithGatewayLogger(log))
+
+	return a
+}
+
+// Start begins serving the HTTP gateway. It blocks until the server stops.
+func (a *App) Start() {
+	a.log.Info("starting RLP Gateway", logger.F("addr", a.cfg.GatewayAddr))
+
+	if err := a.gateway.Start(); err != nil {
+		a.log.Fatalf("gateway stopped unexpectedly: %s", err)
+	}
+}
+
+func (a *App) receive(ctx context.Context, req *v2.EgressBatchRequest) (rlpgateway.EnvelopeStream, error) {
+	creds, err := plumbing.NewClientCredentials(
+		a.cfg.LogsProviderCertPath,
+		a.cfg.LogsProviderKeyPath,
+		a.cfg.LogsProviderCAPath,
+		a.cfg.LogsProviderCommonName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.DialContext(ctx, a.cfg.LogsProviderAddr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	client := v2.NewEgressClient(conn)
+
+	return client.BatchedReceiver(ctx, req)
+}
@@ -1,9 +1,8 @@
 package app
 
 import (
-	"log"
-
 	envstruct "code.cloudfoundry.org/go-envstruct"
+	"code.cloudfoundry.org/loggregator/logger"
 )
 
 // Config holds the configuration for the RLP Gateway
@@ -15,6 +14,9 @@ type Config struct {
 	LogsProviderCommonName string `env:"LOGS_PROVIDER_COMMON_NAME,           report"`
 
 	GatewayAddr string `env:"GATEWAY_ADDR, report"`
+
+	LogFormat string `env:"LOG_FORMAT, report"`
+	LogLevel  string `env:"LOG_LEVEL,  report"`
 }
 
 // LoadConfig will load and return the config from the current environment. If
@@ -23,10 +25,14 @@ func LoadConfig() Config {
 	cfg := Config{
 		GatewayAddr:            "localhost:8088",
 		LogsProviderCommonName: "reverselogproxy",
+		LogFormat:              "text",
+		LogLevel:               "info",
 	}
 
 	if err := envstruct.Load(&cfg); err != nil {
-		log.Fatalf("failed to load config from environment: %s", err)
+		// LOG_FORMAT/LOG_LEVEL haven't been parsed yet, so fall back to the
+		// default text logger for this one fatal path.
+		logger.NewText(logger.LevelInfo).Fatalf("failed to load config from environment: %s", err)
 	}
 
 	envstruct.WriteReport(&cfg)
@@ -0,0 +1,281 @@
+// Package rlpgateway implements an HTTP/JSON bridge in front of the gRPC
+// Reverse Log Proxy (RLP). It lets clients that can't speak gRPC (browsers,
+// curl, simple HTTP clients) read the v2 envelope firehose over a plain
+// `GET /v2/read` endpoint, streaming results as server-sent events.
+package rlpgateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/logger"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/plumbing/v2/jsonpb"
+)
+
+// EnvelopeStream is satisfied by the gRPC stream returned when opening a
+// batched read against the upstream RLP.
+type EnvelopeStream interface {
+	Recv() (*v2.EnvelopeBatch, error)
+}
+
+// Receiver opens a new EnvelopeStream against the upstream RLP for the
+// given request. It is typically backed by a
+// `loggregator_v2.EgressClient.BatchedReceiver` call wrapped with the
+// gateway's mTLS credentials.
+type Receiver func(ctx context.Context, req *v2.EgressBatchRequest) (EnvelopeStream, error)
+
+// Gateway bridges HTTP clients to an upstream RLP.
+type Gateway struct {
+	receiver Receiver
+	addr     string
+	server   *http.Server
+	mux      *http.ServeMux
+
+	log logger.Service
+}
+
+// GatewayOption configures a Gateway.
+type GatewayOption func(*Gateway)
+
+// WithGatewayLogger sets the logger used for diagnostics. It defaults to
+// a text logger writing to stderr at info level.
+func WithGatewayLogger(l logger.Service) GatewayOption {
+	return func(g *Gateway) {
+		g.log = l
+	}
+}
+
+// NewGateway creates a Gateway that reads from the given Receiver and will
+// serve HTTP on addr when Start is called.
+func NewGateway(r Receiver, addr string, opts ...GatewayOption) *Gateway {
+	g := &Gateway{
+		receiver: r,
+		addr:     addr,
+		log:      logger.NewText(logger.LevelInfo),
+	}
+
+	for _, o := range opts {
+		o(g)
+	}
+
+	g.mux = http.NewServeMux()
+	g.mux.HandleFunc("/v2/read", g.read)
+
+	g.server = &http.Server{
+		Addr:    addr,
+		Handler: g,
+	}
+
+	return g
+}
+
+// ServeHTTP makes Gateway an http.Handler, which is useful for wrapping it
+// in additional middleware or exercising it with httptest.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// Start begins serving HTTP traffic. It blocks until the server stops and
+// returns the error from http.Server.Serve (nil after a call to Close).
+func (g *Gateway) Start() error {
+	if err := g.server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Addr returns the address the Gateway is configured to listen on.
+func (g *Gateway) Addr() string {
+	return g.addr
+}
+
+// Close shuts the HTTP server down.
+func (g *Gateway) Close() error {
+	return g.server.Close()
+}
+
+const (
+	// bufferSize bounds how many envelope batches can queue for a slow
+	// client before the gateway starts dropping.
+	bufferSize = 64
+)
+
+func (g *Gateway) read(w http.ResponseWriter, r *http.Request) {
+	sourceID := r.URL.Query().Get("source_id")
+	if sourceID == "" {
+		http.Error(w, "source_id is required", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	req := &v2.EgressBatchRequest{
+		Selectors: selectorsFromQuery(r.URL.Query(), sourceID),
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	stream, err := g.receiver(ctx, req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to connect to logs provider: %s", err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	deterministicName := r.URL.Query().Get("deterministic_name")
+
+	var dropped int64
+	batches := g.readBatches(ctx, stream, &dropped)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case batch, ok := <-batches:
+			if !ok {
+				return
+			}
+
+			if d := atomic.SwapInt64(&dropped, 0); d > 0 {
+				writeEnvelope(w, diagnosticEnvelope(sourceID, d))
+				flusher.Flush()
+			}
+
+			for _, e := range batch.Batch {
+				if deterministicName != "" && !matchesDeterministicName(e, deterministicName) {
+					continue
+				}
+				writeEnvelope(w, e)
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// readBatches pumps the upstream stream into a bounded channel so that a
+// slow HTTP client can't block the upstream gRPC read indefinitely. When
+// the channel is full, incoming batches are dropped and counted in
+// dropped; the caller is responsible for surfacing that count to the
+// client as a diagnostic envelope, mirroring the truncating-buffer
+// pattern doppler uses for its own slow consumers.
+func (g *Gateway) readBatches(ctx context.Context, stream EnvelopeStream, dropped *int64) <-chan *v2.EnvelopeBatch {
+	out := make(chan *v2.EnvelopeBatch, bufferSize)
+
+	go func() {
+		defer close(out)
+
+		for {
+			batch, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					g.log.Error("error receiving from logs provider", logger.F("error", err))
+				}
+				return
+			}
+
+			select {
+			case out <- batch:
+			default:
+				atomic.AddInt64(dropped, 1)
+				g.log.Warn("dropping envelope batch, consumer is too slow")
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// matchesDeterministicName reports whether e carries the given
+// deterministic_name, checking both the current Tags field and the legacy
+// DeprecatedTags field, the same way jsonpb's toWire looks up instance_id.
+func matchesDeterministicName(e *v2.Envelope, name string) bool {
+	if v, ok := e.GetTags()["deterministic_name"]; ok {
+		return v == name
+	}
+	return e.GetDeprecatedTags()["deterministic_name"].GetText() == name
+}
+
+func writeEnvelope(w http.ResponseWriter, e *v2.Envelope) {
+	data, err := jsonpb.MarshalEnvelope(e)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(w, "data: %s\n\n", data)
+}
+
+func diagnosticEnvelope(sourceID string, dropped int64) *v2.Envelope {
+	return &v2.Envelope{
+		SourceId:  sourceID,
+		Timestamp: time.Now().UnixNano(),
+		Message: &v2.Envelope_Log{
+			Log: &v2.Log{
+				Payload: []byte(fmt.Sprintf("rlp-gateway: dropped %d envelope batches for a slow consumer", dropped)),
+				Type:    v2.Log_ERR,
+			},
+		},
+	}
+}
+
+func selectorsFromQuery(q map[string][]string, sourceID string) []*v2.Selector {
+	var selectors []*v2.Selector
+
+	if _, ok := q["log"]; ok {
+		selectors = append(selectors, &v2.Selector{
+			SourceId: sourceID,
+			Message:  &v2.Selector_Log{Log: &v2.LogSelector{}},
+		})
+	}
+
+	if _, ok := q["counter"]; ok {
+		selectors = append(selectors, &v2.Selector{
+			SourceId: sourceID,
+			Message:  &v2.Selector_Counter{Counter: &v2.CounterSelector{}},
+		})
+	}
+
+	if _, ok := q["gauge"]; ok {
+		selectors = append(selectors, &v2.Selector{
+			SourceId: sourceID,
+			Message:  &v2.Selector_Gauge{Gauge: &v2.GaugeSelector{}},
+		})
+	}
+
+	if _, ok := q["timer"]; ok {
+		selectors = append(selectors, &v2.Selector{
+			SourceId: sourceID,
+			Message:  &v2.Selector_Timer{Timer: &v2.TimerSelector{}},
+		})
+	}
+
+	if _, ok := q["event"]; ok {
+		selectors = append(selectors, &v2.Selector{
+			SourceId: sourceID,
+			Message:  &v2.Selector_Event{Event: &v2.EventSelector{}},
+		})
+	}
+
+	if len(selectors) == 0 {
+		selectors = []*v2.Selector{{SourceId: sourceID}}
+	}
+
+	return selectors
+}
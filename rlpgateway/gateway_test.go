@@ -0,0 +1,131 @@
+package rlpgateway_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/plumbing/v2/jsonpb"
+	"code.cloudfoundry.org/loggregator/rlpgateway"
+)
+
+type fakeStream struct {
+	batches chan *v2.EnvelopeBatch
+}
+
+func (f *fakeStream) Recv() (*v2.EnvelopeBatch, error) {
+	b, ok := <-f.batches
+	if !ok {
+		return nil, io.EOF
+	}
+	return b, nil
+}
+
+func TestGatewayStreamsEnvelopesAsSSE(t *testing.T) {
+	stream := &fakeStream{batches: make(chan *v2.EnvelopeBatch, 1)}
+	stream.batches <- &v2.EnvelopeBatch{
+		Batch: []*v2.Envelope{
+			{
+				SourceId: "foo",
+				Message: &v2.Envelope_Log{
+					Log: &v2.Log{Payload: []byte("hello"), Type: v2.Log_OUT},
+				},
+			},
+		},
+	}
+
+	gw := rlpgateway.NewGateway(func(ctx context.Context, req *v2.EgressBatchRequest) (rlpgateway.EnvelopeStream, error) {
+		if req.Selectors[0].SourceId != "foo" {
+			t.Fatalf("unexpected source id: %s", req.Selectors[0].SourceId)
+		}
+		return stream, nil
+	}, "")
+
+	server := httptest.NewServer(gw)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/v2/read?source_id=foo&log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(line, "data: ") {
+		t.Fatalf("expected SSE data line, got %q", line)
+	}
+
+	env, err := jsonpb.UnmarshalEnvelope([]byte(strings.TrimPrefix(line, "data: ")))
+	if err != nil {
+		t.Fatalf("expected valid jsonpb envelope, got %q: %s", line, err)
+	}
+	if string(env.GetLog().GetPayload()) != "hello" {
+		t.Fatalf("expected payload %q, got %q", "hello", env.GetLog().GetPayload())
+	}
+
+	close(stream.batches)
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestGatewayDeterministicNameFilterChecksTags(t *testing.T) {
+	stream := &fakeStream{batches: make(chan *v2.EnvelopeBatch, 1)}
+	stream.batches <- &v2.EnvelopeBatch{
+		Batch: []*v2.Envelope{
+			{
+				SourceId: "foo",
+				Tags:     map[string]string{"deterministic_name": "wanted"},
+				Message: &v2.Envelope_Log{
+					Log: &v2.Log{Payload: []byte("from tags"), Type: v2.Log_OUT},
+				},
+			},
+			{
+				SourceId: "foo",
+				Tags:     map[string]string{"deterministic_name": "unwanted"},
+				Message: &v2.Envelope_Log{
+					Log: &v2.Log{Payload: []byte("should be filtered"), Type: v2.Log_OUT},
+				},
+			},
+		},
+	}
+	close(stream.batches)
+
+	gw := rlpgateway.NewGateway(func(ctx context.Context, req *v2.EgressBatchRequest) (rlpgateway.EnvelopeStream, error) {
+		return stream, nil
+	}, "")
+
+	server := httptest.NewServer(gw)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/v2/read?source_id=foo&log&deterministic_name=wanted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := jsonpb.UnmarshalEnvelope([]byte(strings.TrimPrefix(line, "data: ")))
+	if err != nil {
+		t.Fatalf("expected valid jsonpb envelope, got %q: %s", line, err)
+	}
+	if string(env.GetLog().GetPayload()) != "from tags" {
+		t.Fatalf("expected the envelope matching deterministic_name via Tags, got %q", env.GetLog().GetPayload())
+	}
+}
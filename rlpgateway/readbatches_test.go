@@ -0,0 +1,56 @@
+package rlpgateway
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/loggregator/logger"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+)
+
+type fakeEnvelopeStream struct {
+	batches chan *v2.EnvelopeBatch
+}
+
+func (f *fakeEnvelopeStream) Recv() (*v2.EnvelopeBatch, error) {
+	b, ok := <-f.batches
+	if !ok {
+		return nil, io.EOF
+	}
+	return b, nil
+}
+
+// TestReadBatchesSurfacesDropsToCaller exercises readBatches as read does:
+// a shared counter it can check between receives off the output channel.
+// A slow consumer must see a non-zero count once it starts draining, or
+// the "drop" diagnostic read emits can never actually fire.
+func TestReadBatchesSurfacesDropsToCaller(t *testing.T) {
+	stream := &fakeEnvelopeStream{batches: make(chan *v2.EnvelopeBatch, bufferSize*2)}
+	for i := 0; i < bufferSize*2; i++ {
+		stream.batches <- &v2.EnvelopeBatch{}
+	}
+	close(stream.batches)
+
+	g := &Gateway{log: logger.NewText(logger.LevelError)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var dropped int64
+	out := g.readBatches(ctx, stream, &dropped)
+
+	// Give the producer goroutine a chance to fill the bounded channel and
+	// start dropping before this (deliberately slow) consumer reads any of
+	// it, mirroring a client that can't keep up.
+	time.Sleep(20 * time.Millisecond)
+
+	for range out {
+	}
+
+	if atomic.LoadInt64(&dropped) == 0 {
+		t.Fatal("expected readBatches to report dropped batches to a slow consumer")
+	}
+}
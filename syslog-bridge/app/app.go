@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/loggregator/logger"
+	"code.cloudfoundry.org/loggregator/plumbing"
+	v2 "code.cloudfoundry.org/loggregator/plumbing/v2"
+	"code.cloudfoundry.org/loggregator/plumbing/syslogbridge"
+	"google.golang.org/grpc"
+)
+
+// App wires together the gRPC connection to the upstream RLP firehose and
+// the syslog bridge that forwards its log envelopes to the configured
+// drains.
+type App struct {
+	cfg Config
+	log logger.Service
+}
+
+// NewApp returns an App configured from cfg, logging through log.
+func NewApp(cfg Config, log logger.Service) *App {
+	return &App{cfg: cfg, log: log}
+}
+
+// Start opens the upstream firehose and forwards every log envelope to
+// the configured drains until the stream ends, at which point it fatally
+// logs.
+func (a *App) Start() {
+	ctx := context.Background()
+
+	creds, err := plumbing.NewClientCredentials(
+		a.cfg.LogsProviderCertPath,
+		a.cfg.LogsProviderKeyPath,
+		a.cfg.LogsProviderCAPath,
+		a.cfg.LogsProviderCommonName,
+	)
+	if err != nil {
+		a.log.Fatalf("failed to load logs provider credentials: %s", err)
+	}
+
+	conn, err := grpc.DialContext(ctx, a.cfg.LogsProviderAddr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		a.log.Fatalf("failed to dial logs provider: %s", err)
+	}
+
+	stream, err := v2.NewEgressClient(conn).BatchedReceiver(ctx, &v2.EgressBatchRequest{
+		Selectors: []*v2.Selector{{Message: &v2.Selector_Log{Log: &v2.LogSelector{}}}},
+	})
+	if err != nil {
+		a.log.Fatalf("failed to open firehose: %s", err)
+	}
+
+	bridge, err := syslogbridge.NewBridge(a.cfg.DrainURLs, nil, a.log)
+	if err != nil {
+		a.log.Fatalf("failed to build syslog bridge: %s", err)
+	}
+
+	a.log.Info("starting syslog bridge", logger.F("drains", len(a.cfg.DrainURLs)))
+
+	if err := bridge.Run(stream); err != nil {
+		a.log.Fatalf("firehose stream ended: %s", err)
+	}
+}
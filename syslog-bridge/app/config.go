@@ -0,0 +1,42 @@
+package app
+
+import (
+	envstruct "code.cloudfoundry.org/go-envstruct"
+	"code.cloudfoundry.org/loggregator/logger"
+)
+
+// Config holds the configuration for the syslog-bridge.
+type Config struct {
+	LogsProviderAddr       string `env:"LOGS_PROVIDER_ADDR,        required, report"`
+	LogsProviderCAPath     string `env:"LOGS_PROVIDER_CA_PATH,     required, report"`
+	LogsProviderCertPath   string `env:"LOGS_PROVIDER_CERT_PATH,   required, report"`
+	LogsProviderKeyPath    string `env:"LOGS_PROVIDER_KEY_PATH,    required, report"`
+	LogsProviderCommonName string `env:"LOGS_PROVIDER_COMMON_NAME,           report"`
+
+	// DrainURLs lists one or more drains to forward every v2 Log envelope
+	// to, e.g. "syslog://host:6514,syslog-tls://host:6515".
+	DrainURLs []string `env:"DRAIN_URLS, required, report"`
+
+	LogFormat string `env:"LOG_FORMAT, report"`
+	LogLevel  string `env:"LOG_LEVEL,  report"`
+}
+
+// LoadConfig will load and return the config from the current environment.
+// If this fails this function will fatally log.
+func LoadConfig() Config {
+	cfg := Config{
+		LogsProviderCommonName: "reverselogproxy",
+		LogFormat:              "text",
+		LogLevel:               "info",
+	}
+
+	if err := envstruct.Load(&cfg); err != nil {
+		// LOG_FORMAT/LOG_LEVEL haven't been parsed yet, so fall back to the
+		// default text logger for this one fatal path.
+		logger.NewText(logger.LevelInfo).Fatalf("failed to load config from environment: %s", err)
+	}
+
+	envstruct.WriteReport(&cfg)
+
+	return cfg
+}